@@ -0,0 +1,78 @@
+package flagconf_test
+
+import (
+	"flag"
+	"path/filepath"
+	"testing"
+
+	"go.senan.xyz/flagconf"
+)
+
+func TestParseConfigSetInclude(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "base.conf"), ""+
+		"include extra.conf\n"+
+		"arr one\n",
+	)
+	writeFile(t, filepath.Join(dir, "extra.conf"), "arr two\n")
+
+	fl := flag.NewFlagSet("my-app", flag.ContinueOnError)
+	var arr flagArray
+	fl.Var(&arr, "arr", "")
+	if err := fl.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := flagconf.ParseConfigSet(fl, nil, filepath.Join(dir, "base.conf")); err != nil {
+		t.Fatalf("ParseConfigSet: %v", err)
+	}
+	if want := "\"two\", \"one\""; arr.String() != want {
+		t.Errorf("arr = %v, want %v", arr, want)
+	}
+}
+
+func TestParseConfigSetIncludeCycle(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.conf"), "include b.conf\narr a\n")
+	writeFile(t, filepath.Join(dir, "b.conf"), "include a.conf\narr b\n")
+
+	fl := flag.NewFlagSet("my-app", flag.ContinueOnError)
+	var arr flagArray
+	fl.Var(&arr, "arr", "")
+	if err := fl.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := flagconf.ParseConfigSet(fl, nil, filepath.Join(dir, "a.conf")); err != nil {
+		t.Fatalf("ParseConfigSet: %v", err)
+	}
+	if want := "\"b\", \"a\""; arr.String() != want {
+		t.Errorf("arr = %v, want %v", arr, want)
+	}
+}
+
+func TestParseConfigSetMultiLayering(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.conf")
+	prod := filepath.Join(dir, "prod.conf")
+	writeFile(t, base, "listen-addr :8080\narr base\n")
+	writeFile(t, prod, "listen-addr :9090\narr prod\n")
+
+	fl := flag.NewFlagSet("my-app", flag.ContinueOnError)
+	addr := fl.String("listen-addr", "", "")
+	var arr flagArray
+	fl.Var(&arr, "arr", "")
+	if err := fl.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := flagconf.ParseConfigSetMulti(fl, nil, []string{base, prod}); err != nil {
+		t.Fatalf("ParseConfigSetMulti: %v", err)
+	}
+	if got, want := *addr, ":9090"; got != want {
+		t.Errorf("listen-addr = %q, want %q (later file should win for scalars)", got, want)
+	}
+	if want := "\"base\", \"prod\""; arr.String() != want {
+		t.Errorf("arr = %v, want %v (array flags should append across files)", arr, want)
+	}
+}
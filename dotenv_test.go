@@ -0,0 +1,83 @@
+package flagconf_test
+
+import (
+	"flag"
+	"path/filepath"
+	"testing"
+
+	"go.senan.xyz/flagconf"
+)
+
+func TestParseDotEnvSet(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".env")
+	writeFile(t, path, ""+
+		"# a comment\n"+
+		"export MY_APP_SOME_STRING=\"line one\\nline two\"\n"+
+		"MY_APP_LITERAL='line one\\nline two'\n"+
+		"MY_APP_STRING_ARRAY=one,two\\,three\n",
+	)
+
+	fl := flag.NewFlagSet("my-app", flag.ContinueOnError)
+	str := fl.String("some-string", "", "")
+	literal := fl.String("literal", "", "")
+	var arr flagArray
+	fl.Var(&arr, "string-array", "")
+
+	if err := flagconf.ParseDotEnvSet(fl, nil, path); err != nil {
+		t.Fatalf("ParseDotEnvSet: %v", err)
+	}
+
+	if want := "line one\nline two"; *str != want {
+		t.Errorf("some-string = %q, want %q", *str, want)
+	}
+	if want := `line one\nline two`; *literal != want {
+		t.Errorf("literal = %q, want %q", *literal, want)
+	}
+	if want := `"one", "two,three"`; arr.String() != want {
+		t.Errorf("string-array = %v, want %v", arr, want)
+	}
+}
+
+func TestParseDotEnvSetEscapedBackslashBeforeDelimiter(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".env")
+	writeFile(t, path, "MY_APP_ARR=\"a\\\\,b\"\n")
+
+	fl := flag.NewFlagSet("my-app", flag.ContinueOnError)
+	var arr flagArray
+	fl.Var(&arr, "arr", "")
+
+	if err := flagconf.ParseDotEnvSet(fl, nil, path); err != nil {
+		t.Fatalf("ParseDotEnvSet: %v", err)
+	}
+	if want := `"a\\", "b"`; arr.String() != want {
+		t.Errorf("arr = %v, want %v (escaped backslash before the delimiter must not swallow the split)", arr, want)
+	}
+}
+
+func TestParseDotEnvSetCLITakesPriority(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".env")
+	writeFile(t, path, "MY_APP_SOME_STRING=from-dotenv\n")
+
+	fl := flag.NewFlagSet("my-app", flag.ContinueOnError)
+	str := fl.String("some-string", "", "")
+	if err := fl.Parse([]string{"-some-string", "from-cli"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := flagconf.ParseDotEnvSet(fl, nil, path); err != nil {
+		t.Fatalf("ParseDotEnvSet: %v", err)
+	}
+	if *str != "from-cli" {
+		t.Errorf("some-string = %q, want %q", *str, "from-cli")
+	}
+}
+
+func TestParseDotEnvSetMissingFile(t *testing.T) {
+	fl := flag.NewFlagSet("my-app", flag.ContinueOnError)
+	if err := flagconf.ParseDotEnvSet(fl, nil, filepath.Join(t.TempDir(), "missing.env")); err != nil {
+		t.Fatalf("ParseDotEnvSet: %v", err)
+	}
+}
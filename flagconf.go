@@ -43,6 +43,53 @@ flagconf provides extensions to Go's flag package to support prefixed environmen
 	$ env MY_APP_CONFIG_PATH=conf my-app # provide config path as env var if you like
 
 	$ env MY_APP_SOME_STRING=a my-app -some-bool 1 -config-path conf # stack all 3
+
+# structured config files
+
+Config parsing is pluggable via [ConfigParser] and [ParseConfigSetWith]. The default parser understands
+the line-oriented format above; the go.senan.xyz/flagconf/toml, go.senan.xyz/flagconf/yaml, and
+go.senan.xyz/flagconf/json subpackages provide parsers for those formats without pulling their
+dependencies into this package.
+
+	flagconf.ParseConfigSetWith(flag.CommandLine, os.Environ(), *confPath, toml.Parser)
+
+# .env files
+
+[ParseDotEnv] reads a committed .env.example-style file of KEY=VALUE lines without touching the real
+process environment.
+
+	$ cat .env
+	MY_APP_SOME_STRING="str"
+	$ my-app # flagconf.ParseDotEnv(".env") in main
+
+# required flags
+
+[MarkRequired] and [Finalize] enforce that a flag was set from any of the sources above, not just the CLI:
+
+	flagconf.MarkRequired(flag.CommandLine, "some-string")
+	flag.Parse()
+	flagconf.ParseEnv()
+	flagconf.ParseConfig(*confPath)
+	flagconf.Finalize(flag.CommandLine) // errors if some-string was never set
+
+# struct tag registration
+
+[RegisterStruct] registers flags from a tagged struct instead of hand-written Var calls, so the same
+struct doubles as typed config:
+
+	var conf struct {
+	    ListenAddr string `flag:"listen-addr" default:":8080" usage:"bind address" required:"true"`
+	}
+	flagconf.RegisterStruct(flag.CommandLine, &conf)
+	flag.Parse()
+	flagconf.ParseEnv()
+
+# layered config files
+
+A config file can pull in another with "include", and [ParseConfigSetMulti] accepts a list of paths
+directly, for a shared base file plus a per-environment overlay:
+
+	flagconf.ParseConfigSetMulti(flag.CommandLine, os.Environ(), []string{"base.conf", "prod.conf"})
 */
 package flagconf
 
@@ -51,9 +98,11 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 )
 
 // ParseEnv calls [ParseEnvSet] with the global [flag.CommandLine] and [os.Environ]. Note that err can safely be ignored
@@ -96,19 +145,159 @@ func ParseEnvSet(fl *flag.FlagSet, env []string) (err error) {
 		if _, ok := setFlags[f.Name]; ok {
 			return
 		}
-		key := envKeyForFlag(prefix, f.Name)
+		key := envKeyForFlagSet(fl, prefix, f.Name)
 		for _, v := range splitEscape(envMap[key], ",", `\`) {
 			v = expand(v)
 			if err := f.Value.Set(v); err != nil {
 				flagErrs = append(flagErrs, err)
 				continue
 			}
+			markSet(fl, f.Name)
+		}
+	})
+
+	return errors.Join(flagErrs...)
+}
+
+// ParseDotEnv calls [ParseDotEnvSet] with the global [flag.CommandLine] and [os.Environ]. Note that err can safely
+// be ignored if the [flag.ErrorHandling] is not [flag.ContinueOnError].
+func ParseDotEnv(path string) (err error) {
+	return ParseDotEnvSet(flag.CommandLine, os.Environ(), path)
+}
+
+// ParseDotEnvSet visits flags from fl that have not been provided yet, and finds corresponding values in the
+// .env file specified by path.
+//
+// The file is a sequence of KEY=VALUE lines, using the same MY_APP_FOO_BAR naming convention as
+// [ParseEnvSet] (the [flag.FlagSet] prefix is stripped and the remainder mapped back to a flag name).
+// An optional "export " prefix on a line is ignored, letting the same file be sourced by a shell. "#"
+// starts a comment.
+//
+//	export MY_APP_SOME_STRING=str
+//	# a comment
+//	MY_APP_STRING_ARRAY=one,two
+//
+// Values may be double-quoted, in which case \n, \r, \t, \\, and \" escapes are expanded; single-quoted
+// values are taken literally.
+//
+//	MY_APP_SOME_STRING="line one\nline two"
+//	MY_APP_SOME_STRING='line one\nline two' # literal backslash-n, not a newline
+//
+// As with [ParseEnvSet], values are split on "," to populate array flags (escape with a backslash to
+// include a literal comma), and expanded with [os.Expand] and the given env. The comma split always runs
+// before double-quote escapes are expanded, so an escaped backslash immediately before a delimiter (e.g.
+// MY_APP_ARR="a\\,b", a literal trailing backslash followed by an unescaped comma) still splits into two
+// elements rather than having the delimiter swallowed by the unrelated quote escape.
+//
+// It is not an error if the path argument, or the file that it points to, is empty.
+func ParseDotEnvSet(fl *flag.FlagSet, env []string, path string) (err error) {
+	if path == "" {
+		return nil
+	}
+	defer func() {
+		mimicFlagSetError(fl, err)
+	}()
+
+	envMap := genEnvMap(env)
+	expand := func(v string) string {
+		return os.Expand(v, func(k string) string { return envMap[k] })
+	}
+
+	path = expand(path)
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open dotenv file: %w", err)
+	}
+	defer file.Close()
+
+	dotEnv, err := parseDotEnv(file)
+	if err != nil {
+		return fmt.Errorf("parse dotenv: %w", err)
+	}
+
+	prefix := ReadEnvPrefix(fl)
+	setFlags := getSetFlags(fl)
+
+	var flagErrs []error
+	fl.VisitAll(func(f *flag.Flag) {
+		if _, ok := setFlags[f.Name]; ok {
+			return
+		}
+		tokens, ok := dotEnv[envKeyForFlagSet(fl, prefix, f.Name)]
+		if !ok {
+			return
+		}
+		for _, v := range tokens {
+			v = expand(v)
+			if err := f.Value.Set(v); err != nil {
+				flagErrs = append(flagErrs, err)
+				continue
+			}
+			markSet(fl, f.Name)
 		}
 	})
 
 	return errors.Join(flagErrs...)
 }
 
+// parseDotEnv reads the KEY=VALUE lines of a .env file, splitting each value on "," up front (see
+// [splitEscape]) and only then expanding double-quote escapes per resulting element. Doing it in that
+// order, rather than unescaping the whole value before splitting, keeps the backslash that splitEscape
+// treats as a comma-escape from colliding with the unrelated backslash escapes double quotes support.
+func parseDotEnv(r io.Reader) (map[string][]string, error) {
+	out := map[string][]string{}
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		k = strings.TrimSpace(k)
+		v = strings.TrimSpace(v)
+
+		switch {
+		case len(v) >= 2 && v[0] == '"' && v[len(v)-1] == '"':
+			// Hold \\ aside so that splitEscape does not collapse it into a lone backslash that
+			// unescapeDotEnv would then read as the start of another escape, such as \n.
+			inner := strings.ReplaceAll(v[1:len(v)-1], `\\`, "\x02")
+			tokens := splitEscape(inner, ",", `\`)
+			for i, tok := range tokens {
+				tokens[i] = strings.ReplaceAll(unescapeDotEnv(tok), "\x02", `\`)
+			}
+			out[k] = tokens
+		case len(v) >= 2 && v[0] == '\'' && v[len(v)-1] == '\'':
+			out[k] = splitEscape(v[1:len(v)-1], ",", `\`)
+		default:
+			out[k] = splitEscape(v, ",", `\`)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+var dotEnvEscaper = strings.NewReplacer(
+	`\n`, "\n",
+	`\r`, "\r",
+	`\t`, "\t",
+	`\"`, `"`,
+	`\\`, `\`,
+)
+
+func unescapeDotEnv(s string) string {
+	return dotEnvEscaper.Replace(s)
+}
+
 // ParseEnv calls [ParseConfigSet] with the global [flag.CommandLine] and [os.Environ]. Note that err can safely be ignored
 // if the [flag.ErrorHandling] is not [flag.ContinueOnError].
 func ParseConfig(path string) (err error) {
@@ -135,11 +324,72 @@ func ParseConfig(path string) (err error) {
 //
 //	my-flag $HOME/dir
 //
+// A file may pull in another with an "include" directive, resolved relative to the including file
+// (a cycle guard prevents infinite recursion):
+//
+//	include base.conf
+//	my-flag overrides-base
+//
 // It is not an error if the path argument, or the file that it points to, is empty.
 func ParseConfigSet(fl *flag.FlagSet, env []string, path string) (err error) {
 	if path == "" {
 		return nil
 	}
+	return ParseConfigSetMulti(fl, env, []string{path})
+}
+
+// ParseConfigSetMulti behaves like [ParseConfigSet], but layers multiple config files in order, each
+// processed (includes and all) before moving onto the next. Later files take priority over earlier ones
+// for scalar flags, and append for repeated (array) flags, matching the semantics of repeating a key
+// within a single file. Empty paths are ignored, and it is not an error for any path, or the file it
+// points to, to not exist.
+func ParseConfigSetMulti(fl *flag.FlagSet, env []string, paths []string) (err error) {
+	return applyConfig(fl, env, func(expand func(string) string, set func(name, value string) error) error {
+		visited := map[string]struct{}{}
+		for _, path := range paths {
+			if path == "" {
+				continue
+			}
+			if err := readConfigFile(expand(path), visited, expand, set); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ConfigParser decodes a config file from r, calling set once for every flag value found. For array-like
+// values, set should be called once per element so that [flag.Value] backed array flags accumulate as expected.
+//
+// A ConfigParser is free to decode any format it likes; see the go.senan.xyz/flagconf/toml,
+// go.senan.xyz/flagconf/yaml, and go.senan.xyz/flagconf/json subpackages for structured alternatives to
+// the line-oriented format [ParseConfigSet] uses. Those live outside the root package so that flagconf
+// itself stays free of third party dependencies.
+type ConfigParser func(r io.Reader, set func(name, value string) error) error
+
+// ParseConfigSetWith behaves like [ParseConfigSet], but decodes the config file at path using parser
+// instead of the built in line-oriented format. Unlike [ParseConfigSet], it does not understand the
+// "include" directive, since that requires resolving paths relative to the file being read.
+func ParseConfigSetWith(fl *flag.FlagSet, env []string, path string, parser ConfigParser) (err error) {
+	if path == "" {
+		return nil
+	}
+	return applyConfig(fl, env, func(expand func(string) string, set func(name, value string) error) error {
+		file, err := os.Open(expand(path))
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("open config file: %w", err)
+		}
+		defer file.Close()
+		return parser(file, set)
+	})
+}
+
+// applyConfig sets up the expand func and the name/value set func shared by all config loading entry
+// points, then hands them to load to actually read config content and report values.
+func applyConfig(fl *flag.FlagSet, env []string, load func(expand func(string) string, set func(name, value string) error) error) (err error) {
 	defer func() {
 		mimicFlagSetError(fl, err)
 	}()
@@ -149,7 +399,46 @@ func ParseConfigSet(fl *flag.FlagSet, env []string, path string) (err error) {
 		return os.Expand(v, func(k string) string { return envMap[k] })
 	}
 
-	path = expand(path)
+	setFlags := getSetFlags(fl)
+	flags := map[string]*flag.Flag{}
+	fl.VisitAll(func(f *flag.Flag) { flags[f.Name] = f })
+
+	var flagErrs []error
+	set := func(name, value string) error {
+		if _, ok := setFlags[name]; ok {
+			return nil
+		}
+		f, ok := flags[name]
+		if !ok {
+			return nil
+		}
+		if err := f.Value.Set(expand(value)); err != nil {
+			flagErrs = append(flagErrs, err)
+			return nil
+		}
+		markSet(fl, name)
+		return nil
+	}
+
+	if err := load(expand, set); err != nil {
+		return fmt.Errorf("parse config: %w", err)
+	}
+
+	return errors.Join(flagErrs...)
+}
+
+// readConfigFile reads the line-oriented config format used by [ParseConfigSet] from path, honouring
+// "include <path>" directives resolved relative to path's directory. visited is keyed by absolute path
+// and guards against include cycles across the whole call, including across the paths passed to
+// [ParseConfigSetMulti].
+func readConfigFile(path string, visited map[string]struct{}, expand func(string) string, set func(name, value string) error) error {
+	if abs, err := filepath.Abs(path); err == nil {
+		if _, ok := visited[abs]; ok {
+			return nil
+		}
+		visited[abs] = struct{}{}
+	}
+
 	file, err := os.Open(path)
 	if errors.Is(err, os.ErrNotExist) {
 		return nil
@@ -159,7 +448,7 @@ func ParseConfigSet(fl *flag.FlagSet, env []string, path string) (err error) {
 	}
 	defer file.Close()
 
-	config := map[string][]string{}
+	dir := filepath.Dir(path)
 
 	sc := bufio.NewScanner(file)
 	for sc.Scan() {
@@ -173,43 +462,105 @@ func ParseConfigSet(fl *flag.FlagSet, env []string, path string) (err error) {
 		} else {
 			k, v = line[:idx], strings.TrimSpace(line[idx:])
 		}
-		config[k] = append(config[k], v)
-	}
-	if err := sc.Err(); err != nil {
-		return fmt.Errorf("scan config: %w", err)
-	}
-
-	setFlags := getSetFlags(fl)
-
-	var flagErrs []error
-	fl.VisitAll(func(f *flag.Flag) {
-		if _, ok := setFlags[f.Name]; ok {
-			return
-		}
-		for _, v := range config[f.Name] {
-			v = expand(v)
-			if err := f.Value.Set(v); err != nil {
-				flagErrs = append(flagErrs, err)
-				continue
+		if k == "include" {
+			incPath := expand(v)
+			if !filepath.IsAbs(incPath) {
+				incPath = filepath.Join(dir, incPath)
+			}
+			if err := readConfigFile(incPath, visited, expand, set); err != nil {
+				return err
 			}
+			continue
 		}
-	})
-
-	return errors.Join(flagErrs...)
+		if err := set(k, v); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
 }
 
 var ReadEnvPrefix = func(fl *flag.FlagSet) string {
 	return filepath.Base(fl.Name())
 }
 
+// getSetFlags reports the flags set from any source: CLI flags visited by fl, plus any flags previously
+// marked via markSet by [ParseEnvSet], [ParseConfigSet], [ParseConfigSetMulti], [ParseConfigSetWith], or
+// [ParseDotEnvSet].
 func getSetFlags(fl *flag.FlagSet) map[string]struct{} {
 	m := map[string]struct{}{}
 	fl.Visit(func(f *flag.Flag) {
 		m[f.Name] = struct{}{}
 	})
+
+	setRegistryMu.Lock()
+	for name := range setRegistry[fl] {
+		m[name] = struct{}{}
+	}
+	setRegistryMu.Unlock()
+
 	return m
 }
 
+// markSet records that name was set on fl from a non-CLI source, so that later calls to ParseEnvSet,
+// the ParseConfigSet functions, ParseDotEnvSet, and Finalize all see it via getSetFlags.
+func markSet(fl *flag.FlagSet, name string) {
+	setRegistryMu.Lock()
+	defer setRegistryMu.Unlock()
+	if setRegistry[fl] == nil {
+		setRegistry[fl] = map[string]struct{}{}
+	}
+	setRegistry[fl][name] = struct{}{}
+}
+
+// setRegistry and requiredRegistry are keyed by *flag.FlagSet and never pruned, so every FlagSet ever
+// passed to ParseEnvSet, the ParseConfigSet functions, ParseDotEnvSet, or MarkRequired is kept alive, along with its
+// entries, for the life of the process. Fine for the common case of one long-lived FlagSet per program;
+// code that constructs many short-lived FlagSets (tests, for instance) will leak one entry per FlagSet.
+var (
+	setRegistryMu sync.Mutex
+	setRegistry   = map[*flag.FlagSet]map[string]struct{}{}
+
+	requiredRegistryMu sync.Mutex
+	requiredRegistry   = map[*flag.FlagSet][]string{}
+)
+
+// MarkRequired records that each of names must be set on fl, from any source, by the time [Finalize]
+// is called. It does not itself perform any validation.
+func MarkRequired(fl *flag.FlagSet, names ...string) {
+	requiredRegistryMu.Lock()
+	defer requiredRegistryMu.Unlock()
+	requiredRegistry[fl] = append(requiredRegistry[fl], names...)
+}
+
+// Finalize checks that every flag previously passed to [MarkRequired] for fl has been set, whether by
+// CLI, [ParseEnvSet], [ParseConfigSetWith]/[ParseConfigSet], or [ParseDotEnvSet]. If any are missing, it
+// returns an aggregated error naming them, routed through [flag.FlagSet]'s configured [flag.ErrorHandling]
+// the same way CLI parse errors are, via the same mechanism [ParseEnvSet] and [ParseConfigSet] use.
+//
+// Finalize should be called once, after all other parsing for fl is done.
+func Finalize(fl *flag.FlagSet) (err error) {
+	defer func() {
+		mimicFlagSetError(fl, err)
+	}()
+
+	setFlags := getSetFlags(fl)
+
+	requiredRegistryMu.Lock()
+	names := append([]string(nil), requiredRegistry[fl]...)
+	requiredRegistryMu.Unlock()
+
+	var missing []string
+	for _, name := range names {
+		if _, ok := setFlags[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return fmt.Errorf("required flag(s) not set: %s", strings.Join(missing, ", "))
+}
+
 func genEnvMap(env []string) map[string]string {
 	envMap := map[string]string{}
 	for _, en := range env {
@@ -231,14 +582,49 @@ func envKeyForFlag(prefix string, name string) string {
 	return prefix + "_" + name
 }
 
+// envOverride has the same unbounded, keyed-by-*flag.FlagSet lifetime as setRegistry and
+// requiredRegistry above.
+var (
+	envOverrideMu sync.Mutex
+	envOverride   = map[*flag.FlagSet]map[string]string{}
+)
+
+// overrideEnvKey makes ParseEnvSet and ParseDotEnvSet look up name under the exact environment variable
+// key instead of the prefix-derived one. Used by RegisterStruct to honour an `env:"..."` struct tag.
+func overrideEnvKey(fl *flag.FlagSet, name, key string) {
+	envOverrideMu.Lock()
+	defer envOverrideMu.Unlock()
+	if envOverride[fl] == nil {
+		envOverride[fl] = map[string]string{}
+	}
+	envOverride[fl][name] = key
+}
+
+func envKeyForFlagSet(fl *flag.FlagSet, prefix, name string) string {
+	envOverrideMu.Lock()
+	key, ok := envOverride[fl][name]
+	envOverrideMu.Unlock()
+	if ok {
+		return key
+	}
+	return envKeyForFlag(prefix, name)
+}
+
+// splitEscape splits str on sep, treating an occurrence of sep preceded by esc as a literal character
+// rather than a delimiter. esc preceded by esc is itself treated as a literal esc, so that a literal esc
+// immediately before a delimiter (e.g. "a\\,b", a literal trailing backslash followed by an unescaped
+// comma) isn't mistaken for an escaped delimiter and swallows the split.
 func splitEscape(str string, sep, esc string) []string {
 	if str == "" {
 		return nil
 	}
+	str = strings.ReplaceAll(str, esc+esc, "\x01")
 	str = strings.ReplaceAll(str, esc+sep, "\x00")
 	tokens := strings.Split(str, sep)
 	for i, token := range tokens {
-		tokens[i] = strings.ReplaceAll(token, "\x00", sep)
+		token = strings.ReplaceAll(token, "\x00", sep)
+		token = strings.ReplaceAll(token, "\x01", esc)
+		tokens[i] = token
 	}
 	return tokens
 }
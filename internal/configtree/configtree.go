@@ -0,0 +1,62 @@
+// Package configtree walks the generic map / slice / scalar trees produced by decoding formats such as
+// TOML, YAML, and JSON, and reports each leaf value to a flagconf.ConfigParser's set func.
+package configtree
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Walk walks tree, calling set once for every leaf value found. Nested maps have their keys joined with "-"
+// (so server.port becomes the flag name "server-port"), and set is called once per element of a slice so
+// that flag.Value backed array flags accumulate as expected. A nil leaf (a YAML key with no value, a JSON
+// null, and so on) is skipped rather than reported as the string "<nil>", so it leaves the corresponding
+// flag unset rather than failing its Set with a bogus value.
+//
+// Slices and string-keyed maps are handled via reflection rather than asserting a concrete type such as
+// []any, since decoders don't agree on the concrete element type: BurntSushi/toml, for example, decodes
+// a TOML array of tables ([[items]]) as []map[string]interface{}, not []any.
+func Walk(tree any, set func(name, value string) error) error {
+	return walk("", tree, set)
+}
+
+func walk(name string, v any, set func(name, value string) error) error {
+	rv := reflect.ValueOf(v)
+	switch {
+	case rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String:
+		for _, key := range rv.MapKeys() {
+			k := key.String()
+			childName := k
+			if name != "" {
+				childName = name + "-" + k
+			}
+			if err := walk(childName, rv.MapIndex(key).Interface(), set); err != nil {
+				return err
+			}
+		}
+		return nil
+	case rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if err := walk(name, rv.Index(i).Interface(), set); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		if name == "" || v == nil {
+			return nil
+		}
+		return set(name, stringify(v))
+	}
+}
+
+func stringify(v any) string {
+	switch vv := v.(type) {
+	case string:
+		return vv
+	case fmt.Stringer:
+		return vv.String()
+	default:
+		return fmt.Sprint(vv)
+	}
+}
@@ -0,0 +1,50 @@
+package configtree_test
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"go.senan.xyz/flagconf/internal/configtree"
+)
+
+func TestWalk(t *testing.T) {
+	tree := map[string]any{
+		"flag": "value",
+		"server": map[string]any{
+			"port": 8080,
+		},
+		"strs": []any{"a", "b"},
+		// BurntSushi/toml decodes a TOML array of tables ([[items]]) as []map[string]interface{},
+		// not []any, so this must flatten the same way plain []any does.
+		"items": []map[string]any{
+			{"name": "a"},
+			{"name": "b"},
+		},
+		// A nil leaf (e.g. a YAML key with no value, or a JSON null) must be skipped, not stringified
+		// to "<nil>" and passed to set.
+		"unset": nil,
+	}
+
+	var got []string
+	set := func(name, value string) error {
+		got = append(got, name+"="+value)
+		return nil
+	}
+	if err := configtree.Walk(tree, set); err != nil {
+		t.Fatalf("Walk: %v", err)
+	}
+	sort.Strings(got)
+
+	want := []string{
+		"flag=value",
+		"items-name=a",
+		"items-name=b",
+		"server-port=8080",
+		"strs=a",
+		"strs=b",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Walk() = %v, want %v", got, want)
+	}
+}
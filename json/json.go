@@ -0,0 +1,24 @@
+// Package json provides a [flagconf.ConfigParser] for JSON config files.
+package json
+
+import (
+	"encoding/json"
+	"io"
+
+	"go.senan.xyz/flagconf"
+	"go.senan.xyz/flagconf/internal/configtree"
+)
+
+// Parser decodes a JSON document, flattening nested objects into dash-joined flag names (e.g. a
+// server.port key becomes the flag "server-port"), and calling set once per array element.
+//
+// Use it with [flagconf.ParseConfigSetWith]:
+//
+//	flagconf.ParseConfigSetWith(flag.CommandLine, os.Environ(), *confPath, json.Parser)
+var Parser flagconf.ConfigParser = func(r io.Reader, set func(name, value string) error) error {
+	var tree map[string]any
+	if err := json.NewDecoder(r).Decode(&tree); err != nil && err != io.EOF {
+		return err
+	}
+	return configtree.Walk(tree, set)
+}
@@ -0,0 +1,38 @@
+package json_test
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"go.senan.xyz/flagconf/json"
+)
+
+func TestParser(t *testing.T) {
+	r := strings.NewReader(`{
+		"flag": "value",
+		"server": {"port": 8080},
+		"items": [{"name": "a"}, {"name": "b"}]
+	}`)
+
+	var got []string
+	set := func(name, value string) error {
+		got = append(got, name+"="+value)
+		return nil
+	}
+	if err := json.Parser(r, set); err != nil {
+		t.Fatalf("Parser: %v", err)
+	}
+	sort.Strings(got)
+
+	want := []string{
+		"flag=value",
+		"items-name=a",
+		"items-name=b",
+		"server-port=8080",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parser() = %v, want %v", got, want)
+	}
+}
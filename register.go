@@ -0,0 +1,220 @@
+package flagconf
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// RegisterStruct reflects over the struct pointed to by v and registers a [flag.Flag] on fl for each
+// field tagged with `flag:"name"`. Supported field types are string, bool, every int/uint/float width,
+// [time.Duration], []string (comma-separated, using the same backslash-escape rule as [ParseEnvSet]),
+// and any type implementing [flag.Value]. Fields without a flag tag are skipped.
+//
+// A nested struct field is itself tagged with `flag:"..."`, which is used as a dash-joined prefix for
+// its own fields' tags, e.g.:
+//
+//	type Config struct {
+//	    Server struct {
+//	        Port int `flag:"port" default:"8080" usage:"listen port"`
+//	    } `flag:"server"`
+//	}
+//
+// registers the flag "server-port".
+//
+// Other recognised tags, all optional:
+//
+//	default   parsed and applied the same way a CLI value would be, except that a []string default
+//	          is first split on "," and each element appended, where a CLI value appends one element
+//	usage     shown in -help output
+//	env       overrides the environment variable [ParseEnvSet] and [ParseDotEnvSet] look the flag up
+//	          under, instead of the name derived from the [flag.FlagSet] prefix
+//	required  if "true", equivalent to calling [MarkRequired] for the flag
+//
+// After RegisterStruct returns, fl holds plain [flag.Flag]s, so [ParseEnv], [ParseConfig], and
+// [flag.FlagSet.Parse] all continue to work unchanged.
+func RegisterStruct(fl *flag.FlagSet, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("flagconf: RegisterStruct: v must be a non-nil pointer to a struct")
+	}
+	return registerStruct(fl, "", rv.Elem())
+}
+
+func registerStruct(fl *flag.FlagSet, prefix string, rv reflect.Value) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag, ok := field.Tag.Lookup("flag")
+		if !ok {
+			continue
+		}
+		name := tag
+		if prefix != "" {
+			name = prefix + "-" + tag
+		}
+
+		fv := rv.Field(i)
+
+		if fv.CanAddr() {
+			if value, ok := fv.Addr().Interface().(flag.Value); ok {
+				if err := registerValue(fl, name, field, value); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		if fv.Kind() == reflect.Struct && fv.Type() != durationType {
+			if err := registerStruct(fl, name, fv); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := registerLeaf(fl, name, field, fv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func registerValue(fl *flag.FlagSet, name string, field reflect.StructField, value flag.Value) error {
+	if def, ok := field.Tag.Lookup("default"); ok && def != "" {
+		if err := value.Set(def); err != nil {
+			return fmt.Errorf("flagconf: RegisterStruct: default for %q: %w", name, err)
+		}
+	}
+	fl.Var(value, name, field.Tag.Get("usage"))
+	return applyTags(fl, name, field)
+}
+
+func registerLeaf(fl *flag.FlagSet, name string, field reflect.StructField, fv reflect.Value) error {
+	if !reflectValueSupports(fv) {
+		return fmt.Errorf("flagconf: RegisterStruct: field %q has unsupported type %s", name, fv.Type())
+	}
+
+	value := &reflectValue{rv: fv}
+	if def, ok := field.Tag.Lookup("default"); ok && def != "" {
+		tokens := []string{def}
+		if fv.Kind() == reflect.Slice {
+			tokens = splitEscape(def, ",", `\`)
+		}
+		for _, tok := range tokens {
+			if err := value.Set(tok); err != nil {
+				return fmt.Errorf("flagconf: RegisterStruct: default for %q: %w", name, err)
+			}
+		}
+	}
+
+	fl.Var(value, name, field.Tag.Get("usage"))
+	return applyTags(fl, name, field)
+}
+
+func applyTags(fl *flag.FlagSet, name string, field reflect.StructField) error {
+	if env, ok := field.Tag.Lookup("env"); ok && env != "" {
+		overrideEnvKey(fl, name, env)
+	}
+	if req, ok := field.Tag.Lookup("required"); ok && req != "" {
+		required, err := strconv.ParseBool(req)
+		if err != nil {
+			return fmt.Errorf("flagconf: RegisterStruct: required tag for %q: %w", name, err)
+		}
+		if required {
+			MarkRequired(fl, name)
+		}
+	}
+	return nil
+}
+
+// reflectValue adapts an addressable struct field to [flag.Value], so that [flag.FlagSet.Var] can
+// register it directly.
+type reflectValue struct {
+	rv reflect.Value
+}
+
+func reflectValueSupports(rv reflect.Value) bool {
+	if rv.Type() == durationType {
+		return true
+	}
+	if rv.Kind() == reflect.Slice {
+		return rv.Type().Elem().Kind() == reflect.String
+	}
+	switch rv.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+func (v *reflectValue) String() string {
+	if !v.rv.IsValid() {
+		return ""
+	}
+	return fmt.Sprint(v.rv.Interface())
+}
+
+// IsBoolFlag lets bool fields be set with bare "-name" on the CLI, the same as a flag registered via
+// [flag.FlagSet.BoolVar]. See [flag.Value] for the (undocumented outside its source) convention this
+// implements.
+func (v *reflectValue) IsBoolFlag() bool {
+	return v.rv.Kind() == reflect.Bool
+}
+
+func (v *reflectValue) Set(s string) error {
+	switch {
+	case v.rv.Type() == durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.rv.SetInt(int64(d))
+		return nil
+	case v.rv.Kind() == reflect.Slice:
+		v.rv.Set(reflect.Append(v.rv, reflect.ValueOf(s)))
+		return nil
+	}
+
+	switch v.rv.Kind() {
+	case reflect.String:
+		v.rv.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.rv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.rv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.rv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, v.rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.rv.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported kind %s", v.rv.Kind())
+	}
+	return nil
+}
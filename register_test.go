@@ -0,0 +1,107 @@
+package flagconf_test
+
+import (
+	"flag"
+	"io"
+	"testing"
+	"time"
+
+	"go.senan.xyz/flagconf"
+)
+
+func TestRegisterStruct(t *testing.T) {
+	type serverConfig struct {
+		Port int `flag:"port" default:"8080" usage:"listen port"`
+	}
+	var conf struct {
+		ListenAddr string        `flag:"listen-addr" default:":8080" usage:"bind address" env:"LISTEN_ADDR"`
+		Timeout    time.Duration `flag:"timeout" default:"5s"`
+		Tags       []string      `flag:"tags" default:"a,b\\,c"`
+		Verbose    bool          `flag:"verbose" usage:"enable verbose logging"`
+		Server     serverConfig  `flag:"server"`
+		unexported string
+		Skipped    string
+	}
+	_ = conf.unexported
+
+	fl := flag.NewFlagSet("my-app", flag.ContinueOnError)
+	if err := flagconf.RegisterStruct(fl, &conf); err != nil {
+		t.Fatalf("RegisterStruct: %v", err)
+	}
+
+	if got, want := conf.ListenAddr, ":8080"; got != want {
+		t.Errorf("ListenAddr = %q, want %q", got, want)
+	}
+	if got, want := conf.Timeout, 5*time.Second; got != want {
+		t.Errorf("Timeout = %v, want %v", got, want)
+	}
+	if got, want := conf.Tags, []string{"a", "b,c"}; len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("Tags = %v, want %v", got, want)
+	}
+	if got, want := conf.Server.Port, 8080; got != want {
+		t.Errorf("Server.Port = %v, want %v", got, want)
+	}
+	if fl.Lookup("server-port") == nil {
+		t.Error("expected nested flag \"server-port\" to be registered")
+	}
+	if fl.Lookup("skipped") != nil {
+		t.Error("field without a flag tag should not be registered")
+	}
+
+	if err := fl.Parse([]string{"-listen-addr", ":9090", "-verbose"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := conf.ListenAddr, ":9090"; got != want {
+		t.Errorf("ListenAddr after CLI parse = %q, want %q", got, want)
+	}
+	if !conf.Verbose {
+		t.Error("Verbose = false, want true after bare -verbose")
+	}
+}
+
+func TestRegisterStructRequired(t *testing.T) {
+	var conf struct {
+		ListenAddr string `flag:"listen-addr" required:"true"`
+	}
+	fl := flag.NewFlagSet("my-app", flag.ContinueOnError)
+	fl.SetOutput(io.Discard)
+	if err := flagconf.RegisterStruct(fl, &conf); err != nil {
+		t.Fatalf("RegisterStruct: %v", err)
+	}
+	if err := fl.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := flagconf.Finalize(fl); err == nil {
+		t.Fatal("Finalize() = nil, want error for unset required flag")
+	}
+}
+
+func TestRegisterStructEnvOverride(t *testing.T) {
+	var conf struct {
+		ListenAddr string `flag:"listen-addr" env:"LISTEN_ADDR"`
+	}
+	fl := flag.NewFlagSet("my-app", flag.ContinueOnError)
+	if err := flagconf.RegisterStruct(fl, &conf); err != nil {
+		t.Fatalf("RegisterStruct: %v", err)
+	}
+	if err := fl.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	// LISTEN_ADDR, not the prefix-derived MY_APP_LISTEN_ADDR, should be honoured.
+	if err := flagconf.ParseEnvSet(fl, []string{"LISTEN_ADDR=:1111"}); err != nil {
+		t.Fatalf("ParseEnvSet: %v", err)
+	}
+	if got, want := conf.ListenAddr, ":1111"; got != want {
+		t.Errorf("ListenAddr = %q, want %q", got, want)
+	}
+}
+
+func TestRegisterStructUnsupportedType(t *testing.T) {
+	var conf struct {
+		Bad complex128 `flag:"bad"`
+	}
+	fl := flag.NewFlagSet("my-app", flag.ContinueOnError)
+	if err := flagconf.RegisterStruct(fl, &conf); err == nil {
+		t.Fatal("RegisterStruct() = nil, want error for unsupported field type")
+	}
+}
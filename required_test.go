@@ -0,0 +1,52 @@
+package flagconf_test
+
+import (
+	"flag"
+	"io"
+	"testing"
+
+	"go.senan.xyz/flagconf"
+)
+
+func TestFinalizeMissing(t *testing.T) {
+	fl := flag.NewFlagSet("my-app", flag.ContinueOnError)
+	fl.SetOutput(io.Discard)
+	fl.String("some-string", "", "")
+	flagconf.MarkRequired(fl, "some-string")
+
+	if err := fl.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := flagconf.Finalize(fl); err == nil {
+		t.Fatal("Finalize() = nil, want error for unset required flag")
+	}
+}
+
+func TestFinalizeSetFromCLI(t *testing.T) {
+	fl := flag.NewFlagSet("my-app", flag.ContinueOnError)
+	fl.String("some-string", "", "")
+	flagconf.MarkRequired(fl, "some-string")
+
+	if err := fl.Parse([]string{"-some-string", "str"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := flagconf.Finalize(fl); err != nil {
+		t.Errorf("Finalize() = %v, want nil", err)
+	}
+}
+
+func TestFinalizeSetFromEnv(t *testing.T) {
+	fl := flag.NewFlagSet("my-app", flag.ContinueOnError)
+	fl.String("some-string", "", "")
+	flagconf.MarkRequired(fl, "some-string")
+
+	if err := fl.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := flagconf.ParseEnvSet(fl, []string{"MY_APP_SOME_STRING=str"}); err != nil {
+		t.Fatalf("ParseEnvSet: %v", err)
+	}
+	if err := flagconf.Finalize(fl); err != nil {
+		t.Errorf("Finalize() = %v, want nil", err)
+	}
+}
@@ -0,0 +1,24 @@
+// Package toml provides a [flagconf.ConfigParser] for TOML config files.
+package toml
+
+import (
+	"io"
+
+	"github.com/BurntSushi/toml"
+	"go.senan.xyz/flagconf"
+	"go.senan.xyz/flagconf/internal/configtree"
+)
+
+// Parser decodes a TOML document, flattening nested tables into dash-joined flag names (e.g. a
+// [server] table's port key becomes the flag "server-port"), and calling set once per array element.
+//
+// Use it with [flagconf.ParseConfigSetWith]:
+//
+//	flagconf.ParseConfigSetWith(flag.CommandLine, os.Environ(), *confPath, toml.Parser)
+var Parser flagconf.ConfigParser = func(r io.Reader, set func(name, value string) error) error {
+	var tree map[string]any
+	if _, err := toml.NewDecoder(r).Decode(&tree); err != nil {
+		return err
+	}
+	return configtree.Walk(tree, set)
+}
@@ -0,0 +1,24 @@
+// Package yaml provides a [flagconf.ConfigParser] for YAML config files.
+package yaml
+
+import (
+	"io"
+
+	"go.senan.xyz/flagconf"
+	"go.senan.xyz/flagconf/internal/configtree"
+	"gopkg.in/yaml.v3"
+)
+
+// Parser decodes a YAML document, flattening nested mappings into dash-joined flag names (e.g. a
+// server.port mapping becomes the flag "server-port"), and calling set once per sequence element.
+//
+// Use it with [flagconf.ParseConfigSetWith]:
+//
+//	flagconf.ParseConfigSetWith(flag.CommandLine, os.Environ(), *confPath, yaml.Parser)
+var Parser flagconf.ConfigParser = func(r io.Reader, set func(name, value string) error) error {
+	var tree map[string]any
+	if err := yaml.NewDecoder(r).Decode(&tree); err != nil && err != io.EOF {
+		return err
+	}
+	return configtree.Walk(tree, set)
+}